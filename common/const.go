@@ -64,16 +64,23 @@ const (
 
 // OwnerType is the ownership classification axis of a node, orthogonal to
 // EnrollmentState (credential lifecycle) and NodeState (connectivity). It is
-// node-level (shared across every service on the node). Extensible: a future
-// value (e.g. "group"/"org") only reinterprets the paired OwnerID.
+// node-level (shared across every service on the node). Extensible: a new value
+// only reinterprets the paired OwnerID.
 //
-// Invariant: OwnerTypeGlobal <=> OwnerID == ""; OwnerTypeAccount <=> OwnerID != "".
+// Invariant: OwnerTypeGlobal <=> OwnerID == ""; every other OwnerType <=> OwnerID != "".
 // An empty OwnerType is normalized to OwnerTypeGlobal by the framework.
+// IAM groups are scoped to one service, so a group owner is qualified by the
+// service whose IAM namespace holds the group: OwnerID = "<service>/<group>"
+// (e.g. "vpn/ops-team"), the same on every service of the node. A group-owned
+// node follows that group: GroupRename in the owning service's IAM updates
+// OwnerID, and GroupDelete there resets the node to OwnerTypeGlobal (as account
+// deletion does); groups of the same name in other services have no effect.
 type OwnerType string
 
 const (
 	OwnerTypeGlobal  OwnerType = "global"  // operator/framework-owned; OwnerID empty
 	OwnerTypeAccount OwnerType = "account" // owned by a specific account; OwnerID = account id
+	OwnerTypeGroup   OwnerType = "group"   // shared by an IAM group; OwnerID = "<service>/<iam.Group.GroupName>"
 )
 
 // ServiceScope defines the targeting granularity for service management and operations dispatch.
@@ -87,13 +94,20 @@ const (
 	// ServiceScopeNetworkWithSubnetworks (2): target all nodes in the given networks, recursively including subnetworks.
 	ServiceScopeNetworkWithSubnetworks
 
-	// ServiceScopeNodeGroup (3): target all nodes in the given node groups.
+	// ServiceScopeNodeGroup (3): target all nodes in the given node groups,
+	// including the members of their descendant groups.
 	// Required scope for config op methods.
 	ServiceScopeNodeGroup
 
 	// ServiceScopeNode (4): target a few specific nodes by ID.
 	// Required scope for config op methods.
 	ServiceScopeNode
+
+	// ServiceScopeLocation (5): target all nodes at the given physical sites.
+	// Each list entry is a location selector built with LocationSelector(tier, description).
+	// A node matches if its own Location matches, or if it sits in a network (or any
+	// subnetwork of one) whose Location matches. Not valid for config op methods.
+	ServiceScopeLocation
 )
 
 // ServiceSource identifies the origin of a node's active service configuration.
@@ -104,7 +118,8 @@ const (
 	// ServiceConfigSourceNode (1): config is set directly on the node.
 	ServiceConfigSourceNode ServiceSource = 1 + iota
 
-	// ServiceConfigSourceNodeGroup (2): config is inherited from the node's group.
+	// ServiceConfigSourceNodeGroup (2): config is inherited from one of the node's
+	// groups or one of their ancestor groups.
 	ServiceConfigSourceNodeGroup
 )
 
@@ -144,8 +159,14 @@ const (
 	LicenseStatusNotBound LicenseStatus = "not_bound"
 	// LicenseStatusInactive means the license exists but has not been activated.
 	LicenseStatusInactive LicenseStatus = "inactive"
-	// LicenseStatusExpired means the license is outside its valid time range.
+	// LicenseStatusExpired means the license is outside its valid time range and grace period.
 	LicenseStatusExpired LicenseStatus = "expired"
+	// LicenseStatusGrace means the license is past its ValidEndTime but within its
+	// grace period. The service runs degraded: IsLicenseValid and CheckFeature
+	// still succeed, but every quota listed in the license is frozen at current
+	// usage (CheckQuota rejects any increase; with a LicenseQuotaNodes limit, no
+	// node can be created or gain the service). Unlisted quotas stay unlimited.
+	LicenseStatusGrace LicenseStatus = "grace"
 	// LicenseStatusSuspended means the license has been suspended by the license authority.
 	LicenseStatusSuspended LicenseStatus = "suspended"
 	// LicenseStatusInvalid indicates a license has past its deadline to be activated.
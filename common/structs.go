@@ -51,3 +51,11 @@ type Interface struct {
 	Ip   string
 	Tags []NetIfType
 }
+
+// BoundingBox is a geographic rectangle used for location queries.
+// SouthWest and NorthEast are the corners; only Latitude and Longitude are used.
+// A box whose SouthWest.Longitude is greater than NorthEast.Longitude crosses the antimeridian.
+type BoundingBox struct {
+	SouthWest Coordinates
+	NorthEast Coordinates
+}
@@ -2,7 +2,12 @@
 
 package commonapi
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
 
 func MapToStruct[T any](data map[string]interface{}) (*T, error) {
 	jsonStr, err := json.Marshal(data)
@@ -68,3 +73,22 @@ func StructsToMaps[T any](data []*T) ([]map[string]interface{}, error) {
 
 	return toReturn, nil
 }
+
+// LocationSelector builds a ServiceScopeLocation list entry, e.g. "building:HQ-East".
+// tier must be one of LocationTiers; description is matched exactly against Location.Description.
+func LocationSelector(tier, description string) string {
+	return tier + ":" + description
+}
+
+// ParseLocationSelector splits a ServiceScopeLocation list entry back into its tier and description.
+func ParseLocationSelector(selector string) (tier, description string, err error) {
+	tier, description, found := strings.Cut(selector, ":")
+	if !found || description == "" {
+		return "", "", fmt.Errorf("invalid location selector %q, must be 'TIER:DESCRIPTION'", selector)
+	}
+	if !slices.Contains(LocationTiers, tier) {
+		return "", "", fmt.Errorf("invalid location tier %q", tier)
+	}
+
+	return tier, description, nil
+}
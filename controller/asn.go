@@ -3,6 +3,9 @@
 package capi
 
 import (
+	"context"
+	"time"
+
 	commonapi "asn.amiasys.com/asn-service-api/v26/common"
 	"asn.amiasys.com/asn-service-api/v26/iam"
 	"asn.amiasys.com/asn-service-api/v26/log"
@@ -39,10 +42,15 @@ type ASNController interface {
 	// Call once per name in Init().
 	InitTSDB(name string) (commonapi.TSDBHandler, error)
 
-	// InitLocker returns a cluster-wide distributed lock.
+	// InitLocker returns a cluster-wide distributed lock, read/write lock, and
+	// counting semaphore handle.
 	// Call once in Init().
 	InitLocker() (Lock, error)
 
+	// InitKVStore returns the service's watchable cluster-wide key-value store.
+	// Call once in Init().
+	InitKVStore() (KVStore, error)
+
 	// GetIAM returns the IAM instance for account, group, and access management.
 	// Call once in Init().
 	GetIAM() (iam.Instance, error)
@@ -57,14 +65,74 @@ type ASNController interface {
 	// -------------------------------------------------------------------------
 
 	// IsLicenseValid determines whether the current license is valid for this service.
+	// It succeeds for LicenseStatusActive and also for LicenseStatusGrace, in which
+	// listed quotas are frozen; check LicenseInfo.Status to tell them apart rather
+	// than assuming an active license.
 	IsLicenseValid() error
 
 	// UseLicense binds or updates the license used by this service.
+	// Requires the license authority to be reachable; for air-gapped deployments
+	// use ExportLicenseActivationRequest / ImportLicenseActivationResponse.
 	UseLicense(licenseKey string) error
 
+	// ExportLicenseActivationRequest produces an offline activation request for
+	// licenseKey: a blob signed by this controller containing the license key,
+	// MachineID, and a single-use nonce. The blob is ASCII-armored text so it can
+	// be copied through a CLI command or downloaded from the web UI, then carried
+	// to the license authority. The license stays LicenseStatusInactive until the
+	// matching response is imported; a newer request invalidates the previous nonce.
+	ExportLicenseActivationRequest(licenseKey string) ([]byte, error)
+
+	// ImportLicenseActivationResponse verifies an activation response issued by the
+	// license authority for the latest exported request (signature, MachineID,
+	// nonce) and activates the license offline, as UseLicense would online.
+	// Accepts the ASCII-armored text as produced by the authority.
+	ImportLicenseActivationResponse(blob []byte) error
+
 	// GetCurrentLicenseInfo returns the current machine's license snapshot.
 	GetCurrentLicenseInfo() *LicenseInfo
 
+	// CheckFeature reports whether the named feature is entitled and not expired.
+	// Returns ErrFeatureNotLicensed, or the IsLicenseValid error when the license
+	// itself is not valid.
+	CheckFeature(name string) error
+
+	// CheckQuota reports whether requested more units of the named quota fit within
+	// the license limit given current usage. Returns ErrQuotaExceeded otherwise.
+	// It only checks; call ReportQuotaUsage once the units are actually consumed.
+	// The LicenseQuotaNodes quota is tracked and enforced by the framework itself
+	// in CreateNode (and its bulk and claim-token forms) and AddServiceToNode.
+	CheckQuota(name string, requested int64) error
+
+	// ReportQuotaUsage records the current usage of a service-tracked quota.
+	// Returns an error for LicenseQuotaNodes, which the framework tracks.
+	ReportQuotaUsage(name string, used int64) error
+
+	// GetLicenseUsage returns the current usage against every quota in the license.
+	GetLicenseUsage() ([]*LicenseQuotaUsage, error)
+
+	// SetLicenseWarningSchedule sets how long before the license's ValidEndTime
+	// (and again before its GraceEndTime) OnLicenseChanged is called with an
+	// ExpiryWarning. Defaults to 30, 7, and 1 days. An empty schedule disables
+	// warnings. Each point fires at most once per license term.
+	SetLicenseWarningSchedule(before []time.Duration) error
+
+	// GetLicenseHistory returns this service's license events, oldest first:
+	// bindings, renewals, status changes, and delivered expiry warnings.
+	GetLicenseHistory() ([]*LicenseHistoryEntry, error)
+
+	// -------------------------------------------------------------------------
+	// Coordination
+	// For controllers running as several replicated instances.
+	// -------------------------------------------------------------------------
+
+	// Campaign enters the named election among this service's controller instances
+	// and blocks until this instance becomes leader or ctx is done, in which case
+	// ctx.Err() is returned. Elections are scoped to the service; at most one
+	// instance leads a given name at a time. Call from Start() or later, typically
+	// in a goroutine that runs the singleton work until Leadership.Lost() closes.
+	Campaign(ctx context.Context, name string) (Leadership, error)
+
 	// -------------------------------------------------------------------------
 	// Service Lifecycle Management
 	// -------------------------------------------------------------------------
@@ -73,7 +141,8 @@ type ASNController interface {
 	// ensures the service deb is installed (the node fetches it from the
 	// apt repo configured in asn.conf, idempotent), then loads the .so and
 	// triggers Init(). The service is added to the node's service_names. The node
-	// must be online (NodeStateOnline).
+	// must be online (NodeStateOnline). Returns ErrQuotaExceeded if the node would
+	// exceed the license's LicenseQuotaNodes limit.
 	AddServiceToNode(nodeID string) error
 
 	// DeleteServiceFromNode removes this service from the node's install set: it
@@ -140,7 +209,8 @@ type ASNController interface {
 	DeleteConfigOps(serviceScope commonapi.ServiceScope, scopeID string, configOpIDs []string) (resChan <-chan *OpsResponse, paramErr error)
 
 	// ListConfigOps returns config ops directly attached to the given scope.
-	// Does not traverse the group-to-node inheritance hierarchy.
+	// Does not traverse the group-to-node or parent-to-child group hierarchy;
+	// the effective, layered list of a node is Node.ServiceInfo.ConfigOps.
 	// Synchronous; does not fan out to nodes.
 	ListConfigOps(serviceScope commonapi.ServiceScope, scopeID string) ([]ConfigOp, error)
 
@@ -171,21 +241,143 @@ type ASNController interface {
 	// An optional NodeOwnerFilter restricts the returned nodes by ownership; omit
 	// it (pass nothing) for no owner filtering. Owner filtering is the caller's
 	// responsibility for tenant isolation — pass the tenant's OwnerID to scope to
-	// one account; the framework applies the filter but does not otherwise enforce
-	// isolation. At most one filter may be passed; the first is used.
+	// one account, or VisibleToAccountID to include the account's groups; the
+	// framework applies the filter but does not otherwise enforce isolation (use
+	// ForOwner for an enforcing view). At most one filter may be passed; the
+	// first is used.
 	GetNodesOfNetwork(networkID string, withService bool, ownerFilter ...NodeOwnerFilter) (nodes []*Node, links []*Link, err error)
 
+	// QueryNodesNear returns all nodes within radiusMeters of the given point.
+	// A node without its own Location.Coordinates uses those of its nearest enclosing
+	// network; nodes with no resolvable coordinates are excluded.
+	// If withService is true, only nodes that have this service loaded are returned.
+	// Results are ordered by ascending distance from the point.
+	QueryNodesNear(latitude, longitude float32, radiusMeters float64, withService bool) ([]*Node, error)
+
+	// QueryNodesInBox returns all nodes whose resolved coordinates fall inside box.
+	// Coordinates are resolved as in QueryNodesNear.
+	// If withService is true, only nodes that have this service loaded are returned.
+	QueryNodesInBox(box commonapi.BoundingBox, withService bool) ([]*Node, error)
+
 	// SetNodeOwner sets, transfers, or clears a node's ownership (OwnerType +
 	// OwnerID), a node-level attribute decoupled from the enrollment credential
 	// and preserved across unbind/re-enroll. The caller asserts the owner from its
 	// own authenticated context; the framework trusts it and enforces only the
-	// invariant (OwnerTypeAccount requires a non-empty OwnerID; OwnerTypeGlobal
-	// requires it empty; an empty OwnerType is normalized to OwnerTypeGlobal). The
-	// framework does not adjudicate owner identity, entitlement, or tenant
-	// isolation. Ownership also changes via CreateNode and the account-deletion
-	// cascade. Access-sensitive; audited. See workflow/design/PrivateNode.md.
+	// invariant (OwnerTypeAccount and OwnerTypeGroup require a non-empty OwnerID;
+	// OwnerTypeGlobal requires it empty; an empty OwnerType is normalized to
+	// OwnerTypeGlobal). For OwnerTypeGroup it also checks that OwnerID is
+	// "<service>/<group>" and that the group exists in that service's IAM. The framework does not adjudicate owner identity, entitlement, or
+	// tenant isolation. Ownership also changes via CreateNode and the account- and
+	// group-deletion cascades. Access-sensitive; audited. See
+	// workflow/design/PrivateNode.md.
 	SetNodeOwner(req SetNodeOwnerRequest) error
 
+	// InitiateNodeOwnerTransfer opens a pending two-party ownership transfer.
+	// req.FromOwnerType/FromOwnerID must equal the node's current owner (the caller
+	// asserts the initiator as for SetNodeOwner), and the target must satisfy the
+	// OwnerType/OwnerID invariant. At most one transfer per node may be pending.
+	// Ownership is unchanged until the recipient accepts. Audited.
+	InitiateNodeOwnerTransfer(req InitiateOwnerTransferRequest) (*OwnerTransfer, error)
+
+	// AcceptNodeOwnerTransfer completes a pending transfer. recipientType and
+	// recipientID must equal the transfer's ToOwnerType and ToOwnerID, and the
+	// node's owner must still be FromOwnerType/FromOwnerID; the change is then
+	// applied with the same invariant checks as SetNodeOwner. Audited.
+	AcceptNodeOwnerTransfer(transferID string, recipientType commonapi.OwnerType, recipientID string) (*OwnerTransfer, error)
+
+	// RejectNodeOwnerTransfer declines a pending transfer on behalf of the
+	// recipient. recipientType and recipientID must equal the transfer's
+	// ToOwnerType and ToOwnerID. Audited.
+	RejectNodeOwnerTransfer(transferID string, recipientType commonapi.OwnerType, recipientID, reason string) (*OwnerTransfer, error)
+
+	// CancelNodeOwnerTransfer withdraws a pending transfer on behalf of the
+	// initiator. initiatorType and initiatorID must equal the transfer's
+	// FromOwnerType and FromOwnerID. Audited.
+	CancelNodeOwnerTransfer(transferID string, initiatorType commonapi.OwnerType, initiatorID, reason string) (*OwnerTransfer, error)
+
+	// ListNodeOwnerTransfers returns transfers matching filter, newest first.
+	ListNodeOwnerTransfers(filter OwnerTransferFilter) ([]*OwnerTransfer, error)
+
+	// ForOwner returns a tenant-scoped view of this controller that enforces owner
+	// isolation instead of leaving it to the caller. "In the tenant" means owned by
+	// (ownerType, ownerID); for OwnerTypeAccount it also covers nodes of the
+	// account's IAM groups, as NodeOwnerFilter.VisibleToAccountID. On the view:
+	//   - Reads are restricted to the tenant: GetNodesOfNetwork, QueryNodesNear,
+	//     QueryNodesInBox, EvaluateNodeGroupRule, NodeGroup.Nodes returned by
+	//     ListNodeGroups / GetNodeGroupByID, ListExpiringCertificates,
+	//     GetNodeGroupMetrics (aggregates, Nodes, and Missing cover tenant nodes
+	//     only), ExportTopology (tenant nodes, links between them, and groups the
+	//     tenant owns), and QueryAuditLog (records targeting tenant nodes).
+	//     GetNetworks is not filtered; networks are shared.
+	//   - Calls naming a node outside the tenant return ErrNodeNotInTenant:
+	//     GetNodeByID, UpdateNodeMetadata, SetConfigOfNode, AddServiceToNode,
+	//     DeleteServiceFromNode, SendServiceOpsToNode, AddConfigOps /
+	//     UpdateConfigOp / DeleteConfigOps / ListConfigOps with ServiceScopeNode,
+	//     SetNodeOwner, InitiateNodeOwnerTransfer, CancelNodeOwnerTransfer,
+	//     AddNodesToNodeGroup, RemoveNodesFromNodeGroup, MintEnrollmentToken,
+	//     MintEnrollmentTokens (per item), UnbindNode, DeleteNode, GetEnrollmentStatus,
+	//     RotateNodeCertificate, GetNodeMetrics, RenderBootstrapScript (for the
+	//     token's node), RecordAudit (for a "node" target), and ImportTopology (for
+	//     any node in the document).
+	//   - Transfers are the one exception: AcceptNodeOwnerTransfer and
+	//     RejectNodeOwnerTransfer work on the recipient's view, i.e. when the
+	//     tenant is the transfer's ToOwnerType/ToOwnerID, although the node is not
+	//     yet in the tenant. ListNodeOwnerTransfers returns transfers whose From or
+	//     To owner is the tenant.
+	//   - Scope-based fan-out (StartService, StopService, ResetService,
+	//     SendServiceOps) silently narrows to the tenant's nodes.
+	//   - Node groups are visible to every tenant, but each records the tenant
+	//     that created it (NodeGroup.OwnerType / OwnerID); CreateNodeGroup on the
+	//     view sets it to the tenant. ListNodeGroups, GetNodeGroupByID, and
+	//     ListConfigOps with ServiceScopeNodeGroup work on any group. Every call
+	//     that changes a group, its membership, or its config returns
+	//     ErrNodeGroupNotInTenant unless the tenant owns the group:
+	//     UpdateNodeGroupMetadata, DeleteNodeGroup, SetNodeGroupParent (for the
+	//     group and for a non-empty parent), SetNodeGroupPriority,
+	//     SetNodeGroupLayerConfigOps, SetConfigOfNodeGroup, SetNodeGroupRule,
+	//     AddNodesToNodeGroup, RemoveNodesFromNodeGroup, and AddConfigOps /
+	//     UpdateConfigOp / DeleteConfigOps with ServiceScopeNodeGroup.
+	//     SetNodeGroupRule also adds the tenant to the rule's Owner axis.
+	//   - ImportTopology only touches tenant objects: tenant nodes, groups the
+	//     tenant owns (created groups are owned by it), their configs and config
+	//     ops, and links between tenant nodes. It never creates, updates, or
+	//     deletes networks; a document naming a missing network is rejected. With
+	//     Prune it deletes only such tenant objects missing from the document;
+	//     other tenants' nodes and groups, and shared objects, are left alone.
+	//   - Node-creating calls force the owner to the tenant: CreateNode,
+	//     CreateNodes, ImportNodeManifest, and MintClaimToken (the Template owner).
+	//     ListClaimTokens and RevokeClaimToken only see claim tokens minted through
+	//     a view of the same tenant.
+	//   - Service-global calls return an error; use the unscoped controller:
+	//     InitLogger, InitDocDB, InitTSDB, InitLocker, InitKVStore, GetIAM,
+	//     GetSubscription, Campaign, every License Management method, ForOwner,
+	//     SubscribeNodeStateChanges, SubscribeEnrollmentEvents,
+	//     SubscribeNodeOwnerTransfers, SetCertificateRenewalPolicy,
+	//     GetCertificateRenewalPolicy, and SetNodeMetricsPolicy.
+	//
+	// The view is cheap and goroutine-safe; create one per request if convenient.
+	ForOwner(ownerType commonapi.OwnerType, ownerID string) (ASNController, error)
+
+	// SubscribeNodeOwnerTransfers returns a channel that receives a snapshot of a
+	// transfer each time its state changes, including expiry after its TTL.
+	// One-shot: a second call returns an error. The channel is never closed during
+	// normal framework operation.
+	SubscribeNodeOwnerTransfers() (<-chan *OwnerTransfer, error)
+
+	// SetNodeMetricsPolicy configures how the framework scrapes node-side metrics
+	// from services implementing snapi.MetricsProvider, and whether it persists them.
+	SetNodeMetricsPolicy(policy NodeMetricsPolicy) error
+
+	// GetNodeMetrics returns the last metrics scraped from the node.
+	// Returns an error if the service does not publish metrics or none have been scraped yet.
+	GetNodeMetrics(nodeID string) (*NodeMetrics, error)
+
+	// GetNodeGroupMetrics aggregates the last scraped metrics of every member node of
+	// the group, including members of its descendant groups (as ServiceScopeNodeGroup);
+	// a node in several of those groups is counted once. Member nodes that were never
+	// scraped or whose last scrape failed are counted in Missing and left out of Metrics.
+	GetNodeGroupMetrics(nodeGroupID string) (*AggregatedMetrics, error)
+
 	// SubscribeNodeStateChanges returns a channel for node state changes.
 	// One-shot: a second call returns an error.
 	// On subscription, the channel first delivers a NodeStateChange for every node's current state
@@ -206,6 +398,7 @@ type ASNController interface {
 	// -------------------------------------------------------------------------
 
 	// CreateNodeGroup creates a node group scoped to this service within the given network.
+	// The group is OwnerTypeGlobal, or owned by the tenant when created through ForOwner.
 	CreateNodeGroup(networkID, name, description, metadata string) error
 
 	// ListNodeGroups returns all node groups for this service in the given network.
@@ -218,18 +411,52 @@ type ASNController interface {
 	UpdateNodeGroupMetadata(nodeGroupID, metadata string) error
 
 	// DeleteNodeGroup removes the node group. Member nodes are not affected.
+	// Child groups are re-parented to the deleted group's parent.
 	DeleteNodeGroup(nodeGroupID string) error
 
+	// SetNodeGroupParent nests the group under parentID, or makes it top-level when
+	// parentID is empty. The parent must be in the group's network or one of its
+	// ancestor networks (e.g. "all-edge" at the root, "edge-eu" in the EU network,
+	// "edge-eu-fra" in the FRA subnetwork); cycles are rejected. Member nodes are
+	// re-dispatched the resulting config and ConfigOps. ServiceScopeNodeGroup
+	// targeting a parent also reaches the members of its descendant groups.
+	SetNodeGroupParent(nodeGroupID, parentID string) error
+
+	// SetNodeGroupPriority sets the precedence of the group among the other groups
+	// its member nodes belong to. See NodeGroup for the precedence rules.
+	SetNodeGroupPriority(nodeGroupID string, priority int) error
+
+	// SetNodeGroupLayerConfigOps sets NodeGroup.LayerConfigOps and re-dispatches the
+	// resulting ConfigOps to affected member nodes.
+	SetNodeGroupLayerConfigOps(nodeGroupID string, layer bool) error
+
 	// SetConfigOfNodeGroup persists service config for the group.
-	// Member nodes inherit this config unless they have a node-level config override.
+	// Member nodes and child groups inherit this config unless overridden closer to
+	// the node; see NodeGroup for the precedence rules.
 	SetConfigOfNodeGroup(nodeGroupID, config string) error
 
 	// AddNodesToNodeGroup adds the specified nodes to the group.
+	// Membership is additive; nodes keep any other groups they belong to.
+	// Returns an error for a dynamic group (NodeGroup.Rule != nil).
 	AddNodesToNodeGroup(nodeGroupID string, nodeIDs []string) error
 
 	// RemoveNodesFromNodeGroup removes the specified nodes from the group.
+	// Returns an error for a dynamic group (NodeGroup.Rule != nil).
 	RemoveNodesFromNodeGroup(nodeGroupID string, nodeIDs []string) error
 
+	// SetNodeGroupRule turns the group into a dynamic group driven by rule, or back
+	// into a static group when rule is nil (current members are kept).
+	// Membership is evaluated immediately and then on every relevant node change.
+	// Nodes that join receive the group's config and ConfigOps as with
+	// AddNodesToNodeGroup; nodes that leave are re-dispatched the config and
+	// ConfigOps they resolve to without the group (their remaining groups, then
+	// node-level); see NodeGroup for the precedence rules.
+	SetNodeGroupRule(nodeGroupID string, rule *NodeGroupRule) error
+
+	// EvaluateNodeGroupRule returns the IDs of nodes in the network subtree that rule
+	// would currently select, without creating or modifying any group.
+	EvaluateNodeGroupRule(networkID string, rule NodeGroupRule) ([]string, error)
+
 	// EnrollmentAPI -----------------------------------------------------------
 	// Node Enrollment
 	// Service-agnostic onboarding: contribute a static install spec, create a
@@ -240,4 +467,20 @@ type ASNController interface {
 	// (enrollment.go).
 	// -------------------------------------------------------------------------
 	EnrollmentAPI
+
+	// TopologyExportAPI -------------------------------------------------------
+	// Topology Export / Import
+	// Versioned export of networks, nodes, links, node groups, configs, and
+	// config ops, and a dry-run/apply import of the same document. See
+	// TopologyExportAPI (export.go).
+	// -------------------------------------------------------------------------
+	TopologyExportAPI
+
+	// AuditAPI ----------------------------------------------------------------
+	// Audit Log
+	// Query the framework audit trail of enrollment, ownership, config,
+	// lifecycle, and ops calls, and append service-defined records to it. See
+	// AuditAPI (audit.go).
+	// -------------------------------------------------------------------------
+	AuditAPI
 }
@@ -0,0 +1,75 @@
+// Copyright 2026 Amiasys Corporation and/or its affiliates. All rights reserved.
+
+package capi
+
+import "time"
+
+// AuditAPI exposes the framework's audit trail, embedded in ASNController.
+// The framework records every access-sensitive call (those documented as
+// "audited") and every enrollment, ownership, config, lifecycle, and ops call
+// made by a service. A service reads the records concerning itself and its
+// nodes, and may append its own domain events to the same trail.
+//
+// Records are append-only; neither the framework nor a service can modify or
+// delete them through this API.
+type AuditAPI interface {
+	// QueryAuditLog returns records matching filter, newest first. At most
+	// filter.Limit records are returned; pass the returned nextPageToken as
+	// filter.PageToken to continue. nextPageToken is empty on the last page.
+	// Only records of the calling service and of framework actions on its nodes
+	// are visible.
+	QueryAuditLog(filter AuditFilter) (records []*AuditRecord, nextPageToken string, err error)
+
+	// RecordAudit appends a service-defined record. The framework sets ID,
+	// Timestamp, and Service, and forces Category to AuditCategoryService;
+	// Action and Actor are required.
+	RecordAudit(record AuditRecord) error
+}
+
+// AuditCategory groups audit records by functional area.
+type AuditCategory string
+
+const (
+	AuditCategoryEnrollment AuditCategory = "enrollment" // CreateNode, tokens, RenderBootstrapScript, UnbindNode, DeleteNode, certificates
+	AuditCategoryOwnership  AuditCategory = "ownership"  // SetNodeOwner and ownership cascades
+	AuditCategoryConfig     AuditCategory = "config"     // SetConfigOf*, config ops, node groups
+	AuditCategoryLifecycle  AuditCategory = "lifecycle"  // AddServiceToNode, DeleteServiceFromNode, Start/Stop/ResetService
+	AuditCategoryOps        AuditCategory = "ops"        // SendServiceOps, SendServiceOpsToNode
+	AuditCategoryLock       AuditCategory = "lock"       // Lock.ForceUnlock
+	AuditCategoryService    AuditCategory = "service"    // recorded by a service via RecordAudit
+)
+
+// AuditRecord is one entry of the audit trail.
+type AuditRecord struct {
+	ID        string
+	Timestamp time.Time
+	Service   string // service that made the call or recorded the event; empty for framework-internal actions
+	Category  AuditCategory
+	// Action is the API method (e.g. "UnbindNode") or, for AuditCategoryService,
+	// a service-defined event name.
+	Action string
+	// Actor is the account, CLI user, or "framework" on whose behalf the action ran.
+	Actor  string
+	Reason string
+	// TargetType and TargetID identify the affected object, e.g. ("node", nodeID).
+	TargetType string
+	TargetID   string
+	// Error is the failure message; empty when the action succeeded.
+	Error string
+	// Details is an opaque, action-specific string (typically JSON).
+	Details string
+}
+
+// AuditFilter selects records for QueryAuditLog. Each slice lists alternatives
+// (OR); an empty slice or zero time is not filtered; set fields combine with AND.
+type AuditFilter struct {
+	Categories []AuditCategory
+	Actions    []string
+	Actors     []string
+	TargetIDs  []string
+	Since      time.Time
+	Until      time.Time
+
+	Limit     int // 0 => framework default
+	PageToken string
+}
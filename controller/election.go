@@ -0,0 +1,32 @@
+// Copyright 2026 Amiasys Corporation and/or its affiliates. All rights reserved.
+
+package capi
+
+// Leadership is this controller instance's leadership of one election, returned
+// by ASNController.Campaign(). When the framework runs several controller
+// instances, use it to run singleton background work (metric snapshots,
+// reconcilers, subscription webhooks) exactly once cluster-wide.
+//
+// A handle is single-use: once leadership is lost or resigned it never becomes
+// leader again; call Campaign again to re-enter the election.
+type Leadership interface {
+	// Name returns the election name passed to Campaign.
+	Name() string
+
+	// IsLeader reports whether this instance still holds leadership.
+	IsLeader() bool
+
+	// Lost returns a channel that is closed when leadership ends, whether lost
+	// (e.g. the instance could not renew its lease in time) or resigned. Stop the
+	// singleton work when it closes.
+	Lost() <-chan struct{}
+
+	// FencingToken returns the term's fencing token, strictly increasing across
+	// successive leaders of the same election. Pass it as
+	// KVFence{Election: Name(), FencingToken: ...} to fence KVStore writes.
+	FencingToken() uint64
+
+	// Resign gives up leadership so another instance can be elected.
+	// Idempotent.
+	Resign() error
+}
@@ -2,12 +2,22 @@
 
 package capi
 
-import commonapi "asn.amiasys.com/asn-service-api/v26/common"
+import (
+	"errors"
+	"time"
+
+	commonapi "asn.amiasys.com/asn-service-api/v26/common"
+)
+
+// ErrTokenBindingMismatch is returned by RenderBootstrapScript when the device
+// facts in the request do not satisfy the token's TokenBinding.
+var ErrTokenBindingMismatch = errors.New("device does not match token binding")
 
 // EnrollmentAPI is the framework's service-agnostic node onboarding surface,
 // embedded in ASNController. A service uses it to create framework-owned node
-// identities, mint single-use enrollment tokens, render bootstrap scripts,
-// unbind nodes for re-enrollment, and permanently delete nodes.
+// identities, mint single-use enrollment tokens (optionally device-bound) or
+// multi-use claim tokens, render bootstrap scripts, unbind nodes for
+// re-enrollment, and permanently delete nodes.
 //
 // The service entry is single-service: a service enrolls nodes only for itself.
 // CreateNode therefore takes no service list, and the token / script methods take
@@ -17,8 +27,9 @@ import commonapi "asn.amiasys.com/asn-service-api/v26/common"
 //
 // The framework owns node-key minting, lazy certificate signing, and ASN-core
 // rendering; the service never mints keys, signs certificates, or re-renders the
-// core. It only relays the returned bytes (optionally wrapping them with its own
-// service-owned config layer in the service-entry flow).
+// core. It only relays the returned bytes; a service-owned config layer is
+// supplied as a structured BootstrapOverlay in RenderScriptRequest rather than
+// by editing the rendered bytes.
 //
 // Node ownership (OwnerType + OwnerID) is a node-level attribute set through
 // CreateNode below or SetNodeOwner (OwnershipAPI); it is decoupled from the
@@ -42,15 +53,37 @@ type EnrollmentAPI interface {
 	// On the AllowExisting path UpdateInfo governs the node's shared attributes:
 	// when set, the request's Type and Label overwrite the existing values (which
 	// affects every service on the node); when unset they are left unchanged.
+	// A node that would exceed the license's LicenseQuotaNodes limit is rejected
+	// with ErrQuotaExceeded; this applies equally to CreateNodes,
+	// ImportNodeManifest, and claim-token redemption.
 	CreateNode(req CreateNodeRequest) (*NodeIdentity, error)
 
 	// MintEnrollmentToken issues a single-use, script-fetch token bound to an
 	// EXISTING node. Allowed only when the node is EnrollmentStateUnbound (no
 	// valid certificate); enrollment is non-reentrant. A fresh token supersedes a
 	// prior unused token. To re-enroll a bound node, call UnbindNode first. Does
-	// not create a node.
+	// not create a node. An optional Binding restricts which device may redeem it.
 	MintEnrollmentToken(req MintTokenRequest) (*EnrollmentToken, error)
 
+	// MintClaimToken issues a multi-use claim token for factory-imaged fleets. It
+	// is bound to no node: each successful RenderBootstrapScript with it creates a
+	// node from the token's template (as CreateNode, named from the device's
+	// serial number) and enrolls it, until MaxUses is reached or the token
+	// expires. A device whose derived node was created by this same token
+	// (ClaimToken.NodeIDs) and is EnrollmentStateUnbound re-enrolls that node
+	// without consuming a use; one that is already enrolled is rejected. If the
+	// derived name belongs to any node the token did not create, the redemption
+	// is rejected and audited: a claim token never takes over an existing node.
+	MintClaimToken(req MintClaimTokenRequest) (*ClaimToken, error)
+
+	// RevokeClaimToken invalidates a claim token. Nodes already created with it
+	// are not affected. Access-sensitive; audited.
+	RevokeClaimToken(tokenID, reason string) error
+
+	// ListClaimTokens returns the calling service's claim tokens, including
+	// exhausted and expired ones that have not yet been purged.
+	ListClaimTokens() ([]*ClaimToken, error)
+
 	// UnbindNode revokes the node's current certificate and cancels any
 	// outstanding token, returning the node to EnrollmentStateUnbound so it can
 	// enroll again. It does NOT delete the node: identity, service eligibility,
@@ -76,11 +109,92 @@ type EnrollmentAPI interface {
 	// (unpersisted) node key, lazily signs the node certificate, and renders asnsn
 	// plus the deb of every service in the node's service_names (install specs
 	// from asn.conf). The script is idempotent. The service serves the returned
-	// bytes itself. Never creates a node.
+	// bytes itself. Never creates a node, except when redeeming a claim token.
+	// req.Format selects the packaging; every format carries the same asnsn and
+	// service-deb install spec and the same signed node credentials.
+	// req.Overlay, if set, is embedded after the debs are installed and before
+	// asnsn first starts; the result, overlay included, is signed as a whole.
+	//
+	// If the token carries a TokenBinding, the device facts the service relays in
+	// req must satisfy every set constraint; otherwise the call fails with
+	// ErrTokenBindingMismatch, the token is NOT consumed, and the attempt is
+	// audited.
 	RenderBootstrapScript(req RenderScriptRequest) (*BootstrapScript, error)
 
 	// GetEnrollmentStatus reads the current enrollment state for a node or token.
 	GetEnrollmentStatus(ref EnrollmentRef) (*EnrollmentStatus, error)
+
+	// CreateNodes is the bulk form of CreateNode. Each request is processed as by
+	// CreateNode and reported in the result at the same index. Without Atomic,
+	// items succeed or fail independently; with Atomic, any item failure rolls back
+	// every effect of the call and all items report that failure: nodes it created
+	// are destroyed, the calling service is removed again from existing nodes it
+	// joined on the AllowExisting path (torn down as by DeleteServiceFromNode if
+	// already installed live), and Type/Label overwritten via UpdateInfo are
+	// restored. Other services on those nodes are never affected by the rollback.
+	// err is non-nil only when the batch itself is rejected (e.g. empty or too large).
+	CreateNodes(req CreateNodesRequest) (results []*CreateNodeResult, err error)
+
+	// MintEnrollmentTokens is the bulk form of MintEnrollmentToken. Each request is
+	// processed independently, with the same preconditions as MintEnrollmentToken,
+	// and reported in the result at the same index.
+	MintEnrollmentTokens(reqs []MintTokenRequest) (results []*MintTokenResult, err error)
+
+	// ImportNodeManifest parses a CSV or JSON device manifest into CreateNodesRequest
+	// items (see NodeManifestEntry), runs CreateNodes, and, when MintTokens is set,
+	// mints a token for every successfully created or matched (AllowExisting) node;
+	// a matched node that is not EnrollmentStateUnbound gets a Token with Error set.
+	// Parse errors are reported per row; rows that fail to parse are not created,
+	// and with Atomic any parse error rejects the whole manifest before anything is
+	// created. Token minting runs after the nodes are committed, so a minting
+	// failure never rolls back nodes, even with Atomic.
+	ImportNodeManifest(req ImportNodeManifestRequest) (*NodeManifestResult, error)
+
+	// SubscribeEnrollmentEvents returns a channel of enrollment lifecycle events for
+	// nodes of the calling service, delivered in real time, including the
+	// timer-driven expiries that SubscribeNodeStateChanges does not signal.
+	// One-shot: a second call returns an error. No initial snapshot is delivered;
+	// use GetEnrollmentStatus for current state. The channel is never closed during
+	// normal framework operation.
+	SubscribeEnrollmentEvents() (<-chan *EnrollmentEvent, error)
+
+	// RotateNodeCertificate re-keys a bound node in band: over its live session
+	// the node generates a new key, the framework signs a new certificate, and
+	// the old certificate is revoked once the node confirms the switch. The node
+	// must be EnrollmentStateBound and online; it stays bound and connected
+	// throughout. Fires EnrollmentEventCertRotated. Access-sensitive; audited.
+	RotateNodeCertificate(nodeID string) (*NodeCertificate, error)
+
+	// ListExpiringCertificates returns the certificates of the calling service's
+	// bound nodes that expire within withinSeconds from now, soonest first.
+	ListExpiringCertificates(withinSeconds int64) ([]*NodeCertificate, error)
+
+	// SetCertificateRenewalPolicy sets the automatic renewal policy for the calling
+	// service's nodes. Renewal uses RotateNodeCertificate; a node that is offline
+	// or fails to re-key is retried and reported via EnrollmentEventCertRenewalFailed.
+	SetCertificateRenewalPolicy(policy CertificateRenewalPolicy) error
+
+	// GetCertificateRenewalPolicy returns the current renewal policy.
+	GetCertificateRenewalPolicy() (*CertificateRenewalPolicy, error)
+}
+
+// NodeCertificate describes a node's current certificate.
+type NodeCertificate struct {
+	NodeID       string
+	SerialNumber string
+	NotBefore    int64
+	NotAfter     int64
+}
+
+// CertificateRenewalPolicy controls automatic node certificate renewal.
+// Disabled by default; expiring certificates are then only reported via
+// EnrollmentEventCertExpiring.
+type CertificateRenewalPolicy struct {
+	Enabled bool
+	// RenewBeforeSeconds is how long before NotAfter renewal starts.
+	RenewBeforeSeconds int64
+	// RetryIntervalSeconds is the wait between attempts after a failure.
+	RetryIntervalSeconds int64
 }
 
 // CreateNodeRequest creates a framework-owned node identity, or (with
@@ -100,7 +214,8 @@ type CreateNodeRequest struct {
 	Label           string
 	// OwnerType/OwnerID assert the new node's owner. Trusted verbatim; the
 	// framework enforces only OwnerTypeGlobal<=>empty OwnerID and
-	// OwnerTypeAccount<=>non-empty OwnerID. Empty OwnerType => OwnerTypeGlobal.
+	// OwnerTypeAccount/OwnerTypeGroup<=>non-empty OwnerID. Empty OwnerType =>
+	// OwnerTypeGlobal.
 	OwnerType commonapi.OwnerType
 	OwnerID   string
 	// AllowExisting makes CreateNode add the calling service to a node that
@@ -118,6 +233,81 @@ type CreateNodeRequest struct {
 	UpdateInfo bool
 }
 
+// CreateNodesRequest creates several nodes in one call; see EnrollmentAPI.CreateNodes.
+type CreateNodesRequest struct {
+	Nodes []CreateNodeRequest
+	// Atomic makes the batch all-or-nothing.
+	Atomic bool
+}
+
+// CreateNodeResult is the outcome of one CreateNodesRequest item.
+type CreateNodeResult struct {
+	NodeName string
+	Identity *NodeIdentity // nil when Error is set
+	// Existing reports that the node already existed and the calling service was
+	// added to it (AllowExisting path).
+	Existing bool
+	Error    error
+}
+
+// MintTokenResult is the outcome of one MintEnrollmentTokens item.
+type MintTokenResult struct {
+	NodeID string
+	Token  *EnrollmentToken // nil when Error is set
+	Error  error
+}
+
+// NodeManifestFormat is the encoding of a device manifest.
+type NodeManifestFormat string
+
+const (
+	NodeManifestFormatCSV  NodeManifestFormat = "csv"
+	NodeManifestFormatJSON NodeManifestFormat = "json"
+)
+
+// NodeManifestEntry is one device in a manifest. CSV manifests carry a header
+// row naming these columns (serial_number, node_name, parent_network_id, type,
+// label, owner_type, owner_id); JSON manifests are an array of objects with the
+// same keys. An empty NodeName defaults to the SerialNumber.
+type NodeManifestEntry struct {
+	SerialNumber    string              `json:"serial_number"`
+	NodeName        string              `json:"node_name"`
+	ParentNetworkID string              `json:"parent_network_id"`
+	Type            commonapi.NodeType  `json:"type"`
+	Label           string              `json:"label"`
+	OwnerType       commonapi.OwnerType `json:"owner_type"`
+	OwnerID         string              `json:"owner_id"`
+}
+
+// ImportNodeManifestRequest imports a device manifest; see EnrollmentAPI.ImportNodeManifest.
+type ImportNodeManifestRequest struct {
+	Content []byte
+	Format  NodeManifestFormat
+	// DefaultParentNetworkID applies to rows without a parent_network_id.
+	DefaultParentNetworkID string
+	// AllowExisting, UpdateInfo and Atomic apply to every row as in CreateNodeRequest / CreateNodesRequest.
+	AllowExisting bool
+	UpdateInfo    bool
+	Atomic        bool
+	// MintTokens mints an enrollment token for each created node, using TokenTTLSeconds.
+	MintTokens      bool
+	TokenTTLSeconds int64
+}
+
+// NodeManifestResult reports an ImportNodeManifest call row by row.
+type NodeManifestResult struct {
+	Rows []*NodeManifestRowResult
+}
+
+// NodeManifestRowResult is the outcome of one manifest row.
+type NodeManifestRowResult struct {
+	Row          int // 1-based data row (header excluded) or array index + 1
+	SerialNumber string
+	Node         *CreateNodeResult // nil when the row failed to parse
+	Token        *MintTokenResult  // nil unless MintTokens was set and the node was created or matched
+	ParseError   error
+}
+
 // NodeIdentity is the persisted node identity returned by CreateNode / UnbindNode.
 type NodeIdentity struct {
 	NodeID          string
@@ -132,6 +322,42 @@ type MintTokenRequest struct {
 	NodeID     string // existing node the token enrolls; required
 	TTLSeconds int64
 	Label      string
+	Binding    *TokenBinding // nil => any device presenting the token may redeem it
+}
+
+// TokenBinding restricts which device may redeem a token. Every non-empty
+// constraint must hold (AND); an empty field is not checked. The facts are
+// compared against those the service relays in RenderScriptRequest.
+type TokenBinding struct {
+	SerialNumber string   // exact match on RenderScriptRequest.DeviceInfo.SerialNumber
+	MACAddress   string   // case-insensitive match on RenderScriptRequest.MACAddress
+	SourceCIDRs  []string // RenderScriptRequest.SourceIP must fall in one of these
+}
+
+// MintClaimTokenRequest mints a multi-use claim token; see EnrollmentAPI.MintClaimToken.
+type MintClaimTokenRequest struct {
+	// Template describes the nodes the token creates. NodeName is used as a
+	// prefix: each node is named NodeName + DeviceInfo.SerialNumber.
+	// AllowExisting and UpdateInfo are ignored.
+	Template   CreateNodeRequest
+	MaxUses    int // must be > 0
+	TTLSeconds int64
+	Label      string
+	// Binding applies to every redemption; SerialNumber and MACAddress must be
+	// empty since the token is shared by many devices.
+	Binding *TokenBinding
+}
+
+// ClaimToken is a multi-use credential that creates and enrolls nodes on first contact.
+type ClaimToken struct {
+	Token     string // returned only by MintClaimToken; empty in ListClaimTokens
+	TokenID   string
+	Label     string
+	MaxUses   int
+	Uses      int
+	ExpiresAt int64
+	Revoked   bool
+	NodeIDs   []string // nodes created through this token; the only nodes it may re-enroll
 }
 
 // UnbindNodeRequest revokes a node's certificate and reopens it for enrollment.
@@ -160,17 +386,74 @@ type EnrollmentToken struct {
 }
 
 // RenderScriptRequest renders the bootstrap script for the token's node.
+// The device facts are relayed by the service from the device's request and are
+// checked against the token's TokenBinding; a claim token requires DeviceInfo.SerialNumber.
 type RenderScriptRequest struct {
-	Token string // presented by the device to the service
+	Token   string            // enrollment or claim token presented by the device to the service
+	Format  BootstrapFormat   // empty => BootstrapFormatShell
+	Overlay *BootstrapOverlay // optional service-owned layer
+
+	DeviceInfo *commonapi.DeviceInfo
+	MACAddress string
+	SourceIP   string
+}
+
+// BootstrapOverlay is the service-owned layer embedded into a bootstrap script.
+// On the device, Files are written, then PostInstall commands run with Env
+// exported. Any failure aborts the bootstrap before asnsn starts.
+// InitialNodeConfig is not an on-device step; it is handled on the controller
+// at render time.
+type BootstrapOverlay struct {
+	Files []BootstrapFile
+	Env   map[string]string
+	// PostInstall is a list of shell commands. In BootstrapFormatAnsible each
+	// becomes a shell task; in BootstrapFormatOCIEnv they run in the entrypoint.
+	PostInstall []string
+	// InitialNodeConfig, if non-empty, is persisted on the controller as the
+	// node-level service config (as SetConfigOfNode) by the render that consumes
+	// the token, so the service's first Start() on the node receives it. It is
+	// applied only while the node has no node-level config yet: a later render
+	// (re-enrollment, claim-token re-enroll) never overwrites a config set since,
+	// whether by SetConfigOfNode or an earlier render.
+	InitialNodeConfig string
 }
 
+// BootstrapFile is a file written on the node by a BootstrapOverlay.
+type BootstrapFile struct {
+	Path    string // absolute path on the node
+	Mode    uint32 // permission bits; 0 => 0644
+	Content []byte
+}
+
+// BootstrapFormat selects how RenderBootstrapScript packages the install spec.
+type BootstrapFormat string
+
+const (
+	// BootstrapFormatShell is a self-contained shell script ("text/x-shellscript").
+	BootstrapFormatShell BootstrapFormat = "shell"
+	// BootstrapFormatCloudInit is cloud-init user-data ("text/cloud-config").
+	BootstrapFormatCloudInit BootstrapFormat = "cloud-init"
+	// BootstrapFormatOCIEnv is an env-file bundle for an OCI image entrypoint that
+	// installs and starts asnsn in the container ("text/plain", KEY=VALUE lines).
+	BootstrapFormatOCIEnv BootstrapFormat = "oci-env"
+	// BootstrapFormatAnsible is an Ansible playbook ("application/yaml"); the
+	// matching inventory snippet is in Attachments["inventory.yml"].
+	BootstrapFormatAnsible BootstrapFormat = "ansible"
+)
+
 // BootstrapScript is the rendered ASN-core install script (asnsn + the node's
 // service debs).
 type BootstrapScript struct {
 	Content      []byte
-	ContentType  string // e.g. "text/x-shellscript"
-	NodeID       string // the existing node this script enrolls / re-keys
-	CertNotAfter int64  // validity of the lazily signed certificate
+	ContentType  string          // e.g. "text/x-shellscript"; follows Format
+	Format       BootstrapFormat // format actually rendered
+	NodeID       string          // the existing node this script enrolls / re-keys
+	CertNotAfter int64           // validity of the lazily signed certificate
+	// Attachments holds additional named artifacts of multi-part formats; nil otherwise.
+	Attachments map[string][]byte
+	// Signature is the framework's detached signature over Content and every
+	// Attachment, verifiable with the controller's CA.
+	Signature []byte
 }
 
 // EnrollmentRef identifies an enrollment by node or token.
@@ -187,3 +470,37 @@ type EnrollmentStatus struct {
 	NodeState       commonapi.NodeState // runtime connectivity
 	LastEventAt     int64
 }
+
+// EnrollmentEventType is the kind of an EnrollmentEvent.
+type EnrollmentEventType string
+
+const (
+	EnrollmentEventTokenMinted       EnrollmentEventType = "token_minted"        // enrollment or claim token issued
+	EnrollmentEventTokenConsumed     EnrollmentEventType = "token_consumed"      // token redeemed by RenderBootstrapScript
+	EnrollmentEventCertSigned        EnrollmentEventType = "cert_signed"         // node certificate lazily signed
+	EnrollmentEventTokenExpired      EnrollmentEventType = "token_expired"       // outstanding token passed its ExpiresAt
+	EnrollmentEventCertExpiring      EnrollmentEventType = "cert_expiring"       // certificate entered the expiry warning window (asn.conf)
+	EnrollmentEventCertRotated       EnrollmentEventType = "cert_rotated"        // certificate re-keyed in band
+	EnrollmentEventCertRenewalFailed EnrollmentEventType = "cert_renewal_failed" // automatic renewal attempt failed
+	EnrollmentEventUnbound           EnrollmentEventType = "unbound"             // node returned to EnrollmentStateUnbound
+	EnrollmentEventDeleted           EnrollmentEventType = "deleted"             // node identity destroyed by DeleteNode
+)
+
+// EnrollmentEvent is delivered on the channel returned by SubscribeEnrollmentEvents().
+// TokenID is empty for events not tied to a token; NodeID is empty for a claim
+// token event before a node is created.
+type EnrollmentEvent struct {
+	Timestamp time.Time
+	Type      EnrollmentEventType
+	TokenID   string
+	NodeID    string
+	// Actor is the service, account, or "framework" (timer-driven events) that
+	// caused the event; Reason is the audit reason when one was given.
+	Actor  string
+	Reason string
+
+	EnrollmentState commonapi.EnrollmentState // node's state after the event
+	CertNotAfter    int64                     // set for cert_signed, cert_expiring and cert_rotated
+	// Error is set for cert_renewal_failed.
+	Error error
+}
@@ -0,0 +1,249 @@
+// Copyright 2026 Amiasys Corporation and/or its affiliates. All rights reserved.
+
+package capi
+
+import (
+	"time"
+
+	commonapi "asn.amiasys.com/asn-service-api/v26/common"
+)
+
+// TopologyDocumentVersion is the schema version written by ExportTopology.
+// ImportTopology accepts documents up to this version.
+const TopologyDocumentVersion = 1
+
+// TopologyExportAPI exports and imports the calling service's fleet intent as a
+// versioned document, so it can be backed up or kept in git, embedded in
+// ASNController.
+//
+// The document carries declarative state only: networks, node identity and
+// ownership, links, node groups, and the service's node/group configs and config
+// ops. It never carries node keys, certificates, enrollment tokens, or runtime
+// state (NodeState, EnrollmentState, ServiceInfo.State, NodeInfo); an imported
+// node that does not yet exist is created as by CreateNode and must enroll.
+// See TopologyDocument for how objects are matched on import.
+type TopologyExportAPI interface {
+	// ExportTopology serializes the selected networks (recursively) and everything
+	// in them into a TopologyDocument in the requested format.
+	ExportTopology(req ExportTopologyRequest) (*TopologyExport, error)
+
+	// ImportTopology compares the document with the current state and returns the
+	// resulting change set. In ImportModeDryRun nothing is modified. In
+	// ImportModeApply the changes are applied in dependency order (networks, nodes,
+	// links, groups, configs, config ops); the call stops at the first failure and
+	// the returned changes record which were applied and which failed. Config and
+	// config op changes are dispatched to online nodes as the equivalent
+	// SetConfigOf* / *ConfigOps calls would.
+	ImportTopology(req ImportTopologyRequest) (*ImportResult, error)
+}
+
+// ExportFormat is the serialization of a TopologyDocument.
+type ExportFormat string
+
+const (
+	ExportFormatYAML ExportFormat = "yaml"
+	ExportFormatJSON ExportFormat = "json"
+)
+
+// ExportTopologyRequest selects what ExportTopology serializes.
+type ExportTopologyRequest struct {
+	NetworkIDs []string     // root of each exported subtree; empty => the whole network tree
+	Format     ExportFormat // empty => ExportFormatYAML
+}
+
+// TopologyExport is the result of ExportTopology.
+type TopologyExport struct {
+	Content     []byte
+	ContentType string // e.g. "application/yaml", "application/json"
+	Document    *TopologyDocument
+}
+
+// TopologyDocument is the exported fleet intent of one service.
+//
+// The document never contains controller-assigned IDs, so it can be imported
+// into a fresh controller. Every object is identified, and every cross-reference
+// resolved, by name:
+//   - Networks by Path, the "/"-joined names from the root network down
+//     (e.g. "corp/eu/fra"); a network's parent is implied by its Path.
+//   - Nodes by Name, unique across the root network tree of their Network.
+//   - Node groups by Network path plus Name, unique within that network.
+//   - Links by their two endpoints (node name + interface).
+//   - Config ops by their ConfigParams value.
+//
+// Group membership has one source of truth: NodeGroupDocument.Nodes. Node
+// entries carry no group field.
+//
+// The *Document types mirror Network, Node, Link, NodeGroup, and NodeGroupRule
+// rather than reusing them, because those structs reference each other by
+// controller-assigned ID and carry runtime state that must never be exported.
+// Each document type has the declarative fields of its struct, with ID
+// references replaced by names; a declarative field added to one of those
+// structs must be added to its document type as well.
+//
+// Field names are the lowerCamelCase json/yaml tags below; empty fields are
+// omitted, no runtime state is ever written, and every list is sorted by its
+// identifying name, so re-exporting an unchanged fleet yields a document that
+// differs only in ExportedAt.
+type TopologyDocument struct {
+	Version     int       `json:"version" yaml:"version"`
+	ServiceName string    `json:"serviceName" yaml:"serviceName"`
+	ExportedAt  time.Time `json:"exportedAt" yaml:"exportedAt"`
+
+	Networks   []*NetworkDocument   `json:"networks,omitempty" yaml:"networks,omitempty"`
+	Nodes      []*NodeDocument      `json:"nodes,omitempty" yaml:"nodes,omitempty"`
+	Links      []*LinkDocument      `json:"links,omitempty" yaml:"links,omitempty"`
+	NodeGroups []*NodeGroupDocument `json:"nodeGroups,omitempty" yaml:"nodeGroups,omitempty"`
+}
+
+// NetworkDocument is the declarative form of a Network.
+type NetworkDocument struct {
+	Path        string            `json:"path" yaml:"path"`
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Tiers       []string          `json:"tiers,omitempty" yaml:"tiers,omitempty"`
+	Location    *LocationDocument `json:"location,omitempty" yaml:"location,omitempty"`
+}
+
+// LocationDocument is the declarative form of a commonapi.Location.
+type LocationDocument struct {
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Tier        string   `json:"tier,omitempty" yaml:"tier,omitempty"`
+	Address     string   `json:"address,omitempty" yaml:"address,omitempty"`
+	Latitude    *float32 `json:"latitude,omitempty" yaml:"latitude,omitempty"`
+	Longitude   *float32 `json:"longitude,omitempty" yaml:"longitude,omitempty"`
+	Altitude    *float32 `json:"altitude,omitempty" yaml:"altitude,omitempty"`
+}
+
+// NodeDocument is the declarative form of a Node: identity, placement, and
+// ownership, plus this service's node-level config and config ops.
+type NodeDocument struct {
+	Name        string              `json:"name" yaml:"name"`
+	Network     string              `json:"network" yaml:"network"` // NetworkDocument.Path
+	Type        commonapi.NodeType  `json:"type,omitempty" yaml:"type,omitempty"`
+	Label       string              `json:"label,omitempty" yaml:"label,omitempty"`
+	Description string              `json:"description,omitempty" yaml:"description,omitempty"`
+	Metadata    string              `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Location    *LocationDocument   `json:"location,omitempty" yaml:"location,omitempty"`
+	OwnerType   commonapi.OwnerType `json:"ownerType,omitempty" yaml:"ownerType,omitempty"`
+	OwnerID     string              `json:"ownerId,omitempty" yaml:"ownerId,omitempty"`
+	Config      string              `json:"config,omitempty" yaml:"config,omitempty"`
+	ConfigOps   []string            `json:"configOps,omitempty" yaml:"configOps,omitempty"`
+}
+
+// LinkDocument is the declarative form of a Link.
+type LinkDocument struct {
+	Description string               `json:"description,omitempty" yaml:"description,omitempty"`
+	Bandwidth   int64                `json:"bandwidth,omitempty" yaml:"bandwidth,omitempty"`
+	From        LinkEndpointDocument `json:"from" yaml:"from"`
+	To          LinkEndpointDocument `json:"to" yaml:"to"`
+}
+
+// LinkEndpointDocument is the declarative form of a LinkNode.
+type LinkEndpointDocument struct {
+	Node      string `json:"node" yaml:"node"` // NodeDocument.Name
+	Interface string `json:"interface,omitempty" yaml:"interface,omitempty"`
+}
+
+// NodeGroupRef references a node group by its network path and name.
+type NodeGroupRef struct {
+	Network string `json:"network" yaml:"network"` // NetworkDocument.Path
+	Name    string `json:"name" yaml:"name"`
+}
+
+// NodeGroupDocument is the declarative form of a NodeGroup.
+type NodeGroupDocument struct {
+	NodeGroupRef   `yaml:",inline"`
+	Parent         *NodeGroupRef       `json:"parent,omitempty" yaml:"parent,omitempty"`
+	Description    string              `json:"description,omitempty" yaml:"description,omitempty"`
+	Metadata       string              `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Priority       int                 `json:"priority,omitempty" yaml:"priority,omitempty"`
+	LayerConfigOps bool                `json:"layerConfigOps,omitempty" yaml:"layerConfigOps,omitempty"`
+	OwnerType      commonapi.OwnerType `json:"ownerType,omitempty" yaml:"ownerType,omitempty"`
+	OwnerID        string              `json:"ownerId,omitempty" yaml:"ownerId,omitempty"`
+	// Nodes lists member node names of a static group; it is omitted, and ignored
+	// on import, for a dynamic group, whose membership follows Rule.
+	Nodes     []string               `json:"nodes,omitempty" yaml:"nodes,omitempty"`
+	Rule      *NodeGroupRuleDocument `json:"rule,omitempty" yaml:"rule,omitempty"`
+	Config    string                 `json:"config,omitempty" yaml:"config,omitempty"`
+	ConfigOps []string               `json:"configOps,omitempty" yaml:"configOps,omitempty"`
+}
+
+// NodeGroupRuleDocument is the declarative form of a NodeGroupRule, with
+// networks referenced by path and the Owner filter flattened into its fields.
+type NodeGroupRuleDocument struct {
+	NodeTypes          []commonapi.NodeType  `json:"nodeTypes,omitempty" yaml:"nodeTypes,omitempty"`
+	Labels             []string              `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Networks           []string              `json:"networks,omitempty" yaml:"networks,omitempty"` // NetworkDocument.Path
+	DeviceModels       []string              `json:"deviceModels,omitempty" yaml:"deviceModels,omitempty"`
+	OwnerTypes         []commonapi.OwnerType `json:"ownerTypes,omitempty" yaml:"ownerTypes,omitempty"`
+	OwnerIDs           []string              `json:"ownerIds,omitempty" yaml:"ownerIds,omitempty"`
+	VisibleToAccountID string                `json:"visibleToAccountId,omitempty" yaml:"visibleToAccountId,omitempty"`
+	WithService        bool                  `json:"withService,omitempty" yaml:"withService,omitempty"`
+}
+
+// ImportMode selects whether ImportTopology only reports or also applies changes.
+type ImportMode int
+
+const (
+	ImportModeDryRun ImportMode = iota // compute and return the diff only
+	ImportModeApply                    // compute the diff and apply it
+)
+
+// ImportTopologyRequest imports a document produced by ExportTopology.
+type ImportTopologyRequest struct {
+	Content []byte
+	Format  ExportFormat // empty => detected from Content
+	Mode    ImportMode
+	// Prune deletes objects under the document's networks that the document does
+	// not contain, including config ops whose ConfigParams it does not list and
+	// configs it leaves empty. Without it the import only creates and updates, and
+	// an empty Config leaves the existing config unchanged. On a tenant view
+	// (ASNController.ForOwner) it only prunes the tenant's own objects.
+	Prune bool
+}
+
+// ImportResult is the change set computed (and, in ImportModeApply, applied) by ImportTopology.
+type ImportResult struct {
+	Changes []*TopologyChange
+	// Applied is true only when Mode was ImportModeApply and every change succeeded.
+	Applied bool
+}
+
+// TopologyObjectKind identifies the kind of object a TopologyChange refers to.
+type TopologyObjectKind string
+
+const (
+	TopologyObjectNetwork   TopologyObjectKind = "network"
+	TopologyObjectNode      TopologyObjectKind = "node"
+	TopologyObjectLink      TopologyObjectKind = "link"
+	TopologyObjectNodeGroup TopologyObjectKind = "node_group"
+	TopologyObjectConfig    TopologyObjectKind = "config"
+	TopologyObjectConfigOp  TopologyObjectKind = "config_op"
+)
+
+// TopologyChangeAction is what ImportTopology does to one object.
+type TopologyChangeAction string
+
+const (
+	TopologyChangeCreate TopologyChangeAction = "create"
+	TopologyChangeUpdate TopologyChangeAction = "update"
+	TopologyChangeDelete TopologyChangeAction = "delete"
+)
+
+// TopologyChange is one entry of an import diff.
+type TopologyChange struct {
+	Kind   TopologyObjectKind
+	Action TopologyChangeAction
+	// ID is the existing object's ID; empty for a create. Name is the object's
+	// document reference: network path, node name, "network-path/group-name",
+	// "from-node:if->to-node:if" for a link, or the owning node/group reference
+	// for configs and config ops.
+	ID   string
+	Name string
+	// Fields lists the changed field names for an update.
+	Fields []string
+
+	// Applied reports whether the change was applied; always false in ImportModeDryRun.
+	Applied bool
+	// Error is set when applying the change failed.
+	Error error
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 Amiasys Corporation and/or its affiliates. All rights reserved.
+
+package capi
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrKVKeyNotFound is returned by Get when the key does not exist.
+	ErrKVKeyNotFound = errors.New("kv key not found")
+	// ErrKVRevisionMismatch is returned by CAS when the key's revision differs from the expected one.
+	ErrKVRevisionMismatch = errors.New("kv revision mismatch")
+	// ErrStaleFencingToken is returned by a fenced write whose token is lower than
+	// the highest token already seen for the same fence counter.
+	ErrStaleFencingToken = errors.New("stale fencing token")
+)
+
+// KVStore is a small, watchable, cluster-wide key-value store obtained via
+// ASNController.InitKVStore(). Controller side only. It is meant for
+// coordination state (cursors, feature flags, allocations), not bulk data;
+// use DocDB for the latter.
+//
+// Every write is assigned a store-wide revision that strictly increases, so a
+// watcher can resume from the last revision it processed.
+//
+// Writes accept an optional KVFence tying them to a Lock or Leadership fencing
+// token: the write is rejected with ErrStaleFencingToken if the fence's counter
+// has already moved past the token, by any fenced write, by an acquisition that
+// advanced it (see Lock), or by a newer leadership term.
+// Omit it (pass nothing) for an unfenced write. At most one fence may be passed;
+// the first is used.
+type KVStore interface {
+	// Get returns the entry for key, or ErrKVKeyNotFound.
+	Get(key string) (*KVEntry, error)
+
+	// Put creates or overwrites key and returns the new revision.
+	Put(key string, value []byte, fence ...KVFence) (revision int64, err error)
+
+	// CAS writes key only if its current ModRevision equals expectedRevision
+	// (0 => only if the key does not exist), and returns the new revision.
+	// Returns ErrKVRevisionMismatch otherwise.
+	CAS(key string, value []byte, expectedRevision int64, fence ...KVFence) (revision int64, err error)
+
+	// Delete removes key and returns the revision of the deletion.
+	// Deleting a missing key is not an error.
+	Delete(key string, fence ...KVFence) (revision int64, err error)
+
+	// List returns all entries whose key starts with prefix ("" => all), ordered by key.
+	List(prefix string) ([]*KVEntry, error)
+
+	// Watch streams changes to keys starting with prefix. If fromRevision > 0,
+	// changes after that revision are replayed first, unless they have been
+	// compacted, in which case an error is returned. The channel is closed when
+	// ctx is done.
+	Watch(ctx context.Context, prefix string, fromRevision int64) (<-chan *KVEvent, error)
+}
+
+// KVFence ties a KVStore write to a fencing token. Set either LockKey, for a
+// LockLease token, or Election, for a Leadership.FencingToken(); not both.
+type KVFence struct {
+	// Kind selects the namespace of LockKey, as for Lock.GetLockHolder:
+	// LockKindSemaphore, or LockKindExclusive / LockKindShared for a lock key.
+	Kind    LockKind
+	LockKey string
+	Slot    int // LockLease.Slot for a semaphore; ignored for a lock key
+	// Election is the name passed to ASNController.Campaign.
+	Election     string
+	FencingToken uint64
+}
+
+// KVEntry is one key of the KVStore.
+type KVEntry struct {
+	Key   string
+	Value []byte
+	// CreateRevision is the revision that created the key; ModRevision the last write.
+	CreateRevision int64
+	ModRevision    int64
+	UpdatedAt      time.Time
+}
+
+// KVEventType is the kind of a KVEvent.
+type KVEventType int
+
+const (
+	KVEventPut KVEventType = 1 + iota
+	KVEventDelete
+)
+
+// KVEvent is one change delivered by KVStore.Watch.
+// Entry.Value is nil for KVEventDelete; Entry.ModRevision is the event's revision.
+type KVEvent struct {
+	Type  KVEventType
+	Entry *KVEntry
+}
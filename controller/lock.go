@@ -2,14 +2,177 @@
 
 package capi
 
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrLockHeld is returned by TryLock / TryRLock when the key is held in a
+	// conflicting mode, and by TryAcquire when every semaphore slot is taken.
+	ErrLockHeld = errors.New("lock is held by another holder")
+	// ErrLockNotHeld is returned by Renew, RenewSlot, Unlock, RUnlock, and Release
+	// when identifier does not hold the key, including when its lease has already
+	// expired.
+	ErrLockNotHeld = errors.New("lock is not held by this holder")
+)
+
 // Lock is a cluster-wide distributed lock obtained via ASNController.InitLocker().
-// Controller side only.
+// Controller side only. Besides exclusive locks it provides shared/exclusive
+// (read/write) locking on the same keys and counting semaphores.
+//
+// Every lease-based acquisition returns a fencing token. Pass it along with
+// downstream writes so the target can reject a write carrying a token lower than
+// the highest it has seen for the same counter, i.e. one from a holder whose
+// lease already expired. Tokens only advance where holders conflict:
+//   - A lock key has one counter, advanced by each exclusive acquisition (Lock
+//     advances it too, but does not return it) and by ForceUnlock. A shared hold
+//     gets the counter's current value without advancing it, so concurrent
+//     shared holders carry the same token and the next exclusive holder fences
+//     them all out.
+//   - A semaphore key has one counter per slot (LockLease.Slot, 0 to limit-1),
+//     advanced each time that slot is taken and by ForceUnlock, so concurrent
+//     slot holders never fence each other out.
+//
+// For operators, the framework registers the introspection methods as CLI
+// commands under each service's command in the ASN CLI, next to the commands
+// returned by StaticResource.CLICommands():
+//
+//	lock list [PREFIX]
+//	lock holder KEY [--semaphore]
+//	lock force-unlock KEY --reason REASON [--semaphore]
 type Lock interface {
 	// Lock acquires the lock for the given key.
 	// identifier distinguishes the holder; pass the same value to Unlock to prevent
 	// accidental release by a different caller holding the same key.
+	// Blocks until acquired; the lock has no lease and is held until Unlock.
 	Lock(key, identifier string) error
 
 	// Unlock releases the lock. Only succeeds if identifier matches the value passed to Lock.
 	Unlock(key, identifier string) error
+
+	// TryLock acquires the lock with a lease of ttl without blocking.
+	// Returns ErrLockHeld if another identifier holds it. ttl 0 => framework default.
+	TryLock(key, identifier string, ttl time.Duration) (*LockLease, error)
+
+	// LockWithTimeout acquires the lock with a lease of ttl, blocking until it is
+	// acquired or ctx is done, in which case ctx.Err() is returned.
+	// ttl 0 => framework default.
+	LockWithTimeout(ctx context.Context, key, identifier string, ttl time.Duration) (*LockLease, error)
+
+	// Renew extends an exclusive or shared holder's lease to ttl from now, keeping
+	// its fencing token.
+	// Returns ErrLockNotHeld once the lease has expired; the holder must then
+	// re-acquire and use the new token. ttl 0 => framework default.
+	Renew(key, identifier string, ttl time.Duration) (*LockLease, error)
+
+	// RLock acquires the key in shared (read) mode. Any number of identifiers may
+	// hold it shared at once; an exclusive holder (Lock, TryLock, LockWithTimeout)
+	// excludes them all. Writer-preferring: a new RLock waits while an exclusive
+	// acquisition is waiting. Blocks until acquired; held until RUnlock with no
+	// lease, so a crashed holder keeps it: prefer TryRLock / RLockWithTimeout.
+	RLock(key, identifier string) error
+
+	// TryRLock acquires the key in shared mode with a lease of ttl without
+	// blocking. Returns ErrLockHeld if it is held or awaited exclusively.
+	// ttl 0 => framework default.
+	TryRLock(key, identifier string, ttl time.Duration) (*LockLease, error)
+
+	// RLockWithTimeout acquires the key in shared mode with a lease of ttl,
+	// blocking until it is acquired or ctx is done, in which case ctx.Err() is
+	// returned. ttl 0 => framework default.
+	RLockWithTimeout(ctx context.Context, key, identifier string, ttl time.Duration) (*LockLease, error)
+
+	// RUnlock releases a shared hold. Only succeeds if identifier holds key shared.
+	RUnlock(key, identifier string) error
+
+	// Acquire takes one slot of the counting semaphore key, which admits at most
+	// limit holders cluster-wide, blocking until a slot is free. Semaphore keys are
+	// a separate namespace from lock keys. Every caller must pass the same limit
+	// for a key; a mismatch returns an error. An identifier holds at most one slot
+	// per key; acquiring again is a no-op. The slot has no lease and is held until
+	// Release, so a crashed holder keeps it: prefer TryAcquire / AcquireWithTimeout.
+	Acquire(key, identifier string, limit int) error
+
+	// TryAcquire takes one slot of the semaphore key with a lease of ttl without
+	// blocking. Returns ErrLockHeld if all limit slots are taken.
+	// ttl 0 => framework default.
+	TryAcquire(key, identifier string, limit int, ttl time.Duration) (*LockLease, error)
+
+	// AcquireWithTimeout takes one slot of the semaphore key with a lease of ttl,
+	// blocking until a slot is free or ctx is done, in which case ctx.Err() is
+	// returned. ttl 0 => framework default.
+	AcquireWithTimeout(ctx context.Context, key, identifier string, limit int, ttl time.Duration) (*LockLease, error)
+
+	// RenewSlot extends identifier's semaphore slot lease to ttl from now, keeping
+	// its slot and fencing token.
+	// Returns ErrLockNotHeld once the lease has expired and the slot was freed.
+	// ttl 0 => framework default.
+	RenewSlot(key, identifier string, ttl time.Duration) (*LockLease, error)
+
+	// Release returns identifier's slot of the semaphore key.
+	// Returns ErrLockNotHeld if identifier holds no slot.
+	Release(key, identifier string) error
+
+	// ListLocks returns every held lock, shared lock, and semaphore of this service
+	// whose key starts with prefix ("" => all), ordered by key then Kind. A string
+	// held both as a lock key and as a semaphore key yields two entries.
+	ListLocks(prefix string) ([]*LockInfo, error)
+
+	// GetLockHolder returns the current holders of key, or nil if it is free.
+	// kind selects the namespace: LockKindSemaphore for semaphore keys, and
+	// LockKindExclusive or LockKindShared (equivalently) for lock keys, in which
+	// case the returned Kind reports the mode the key is actually held in.
+	GetLockHolder(kind LockKind, key string) (*LockInfo, error)
+
+	// ForceUnlock administratively releases every hold on key in the namespace
+	// selected by kind (as for GetLockHolder), regardless of identifier. The key's
+	// fencing counter (every slot's, for a semaphore) is advanced so writes from
+	// evicted holders can be rejected,
+	// and their next Renew, RenewSlot, Unlock, RUnlock, or Release returns
+	// ErrLockNotHeld. Waiting acquirers are not affected and may then acquire.
+	// reason is required. Access-sensitive; audited.
+	ForceUnlock(kind LockKind, key, reason string) error
+}
+
+// LockKind is the kind of hold on a key.
+type LockKind string
+
+const (
+	LockKindExclusive LockKind = "exclusive" // Lock, TryLock, LockWithTimeout
+	LockKindShared    LockKind = "shared"    // RLock, TryRLock, RLockWithTimeout
+	LockKindSemaphore LockKind = "semaphore" // Acquire, TryAcquire, AcquireWithTimeout
+)
+
+// LockInfo describes the current holders of one key.
+type LockInfo struct {
+	Key          string
+	Kind         LockKind
+	Limit        int    // semaphore limit; 0 for other kinds
+	FencingToken uint64 // the lock key's counter; 0 for a semaphore, see LockHolder
+	Holders      []*LockHolder
+}
+
+// LockHolder is one holder of a key.
+type LockHolder struct {
+	Identifier string
+	Since      time.Time
+	ExpiresAt  time.Time // zero for holds without a lease
+	// Slot and FencingToken are the semaphore slot held and its counter; zero
+	// for lock keys.
+	Slot         int
+	FencingToken uint64
+}
+
+// LockLease describes a held lease-based lock, shared lock, or semaphore slot.
+// The hold is released automatically at ExpiresAt unless renewed. FencingToken
+// follows the rules in the Lock doc: for a shared hold it is shared with the
+// other current shared holders, and for a semaphore hold it belongs to Slot.
+type LockLease struct {
+	Key          string
+	Identifier   string
+	Slot         int // semaphore slot; 0 for lock keys
+	FencingToken uint64
+	ExpiresAt    time.Time
 }
@@ -60,11 +60,14 @@ type ASNServiceController interface {
 	HandleMessageFromNode(nodeID, messageType, payload string) error
 
 	// GetMetrics returns display-only metrics scoped to the given network.
+	// For metrics published by the nodes themselves, see ASNController.GetNodeMetrics.
 	// Concurrent; must return promptly. Values must be JSON-serializable.
 	// Maintain snapshots in background goroutines; do not compute on the call path.
 	GetMetrics(networkID string) (map[string]string, error)
 
-	// OnLicenseChanged notifies the service that the license content or status changed.
+	// OnLicenseChanged notifies the service that the license content or status changed,
+	// and is also called at each point of the expiry warning schedule
+	// (ASNController.SetLicenseWarningSchedule) with license.ExpiryWarning set.
 	OnLicenseChanged(license *LicenseInfo) error
 
 	// Stop gracefully stops the service controller.
@@ -3,11 +3,25 @@
 package capi
 
 import (
+	"errors"
 	"time"
 
 	commonapi "asn.amiasys.com/asn-service-api/v26/common"
 )
 
+var (
+	// ErrNodeNotInTenant is returned by a tenant-scoped controller (ASNController.ForOwner)
+	// when a call names a node that the tenant does not own.
+	ErrNodeNotInTenant = errors.New("node does not belong to tenant")
+	// ErrNodeGroupNotInTenant is returned by a tenant-scoped controller when a call
+	// changes a node group that the tenant does not own.
+	ErrNodeGroupNotInTenant = errors.New("node group does not belong to tenant")
+	// ErrFeatureNotLicensed is returned by CheckFeature when the feature is absent, disabled, or expired.
+	ErrFeatureNotLicensed = errors.New("feature not licensed")
+	// ErrQuotaExceeded is returned when an operation would exceed a license quota.
+	ErrQuotaExceeded = errors.New("license quota exceeded")
+)
+
 // Network represents a network in the topology tree.
 // Networks may be nested: each Network embeds a slice of child Networks,
 // linked to their parent via ParentID.
@@ -32,8 +46,10 @@ type Node struct {
 	State           commonapi.NodeState       // runtime connectivity
 	EnrollmentState commonapi.EnrollmentState // credential lifecycle (orthogonal to State)
 	NetworkID       string
-	NodeGroupID     string // empty if the node is not in a group
+	NodeGroupID     string   // highest-precedence group the node belongs to; empty if the node is not in a group
+	NodeGroupIDs    []string // every group the node is a direct member of, in ascending precedence
 	Description     string
+	Label           string // set via CreateNode; matched by NodeGroupRule.Labels
 	// Metadata is an opaque string set by the service via UpdateNodeMetadata().
 	Metadata string
 	Location *commonapi.Location
@@ -44,7 +60,7 @@ type Node struct {
 	// Ownership axis (node-level, shared across every service on the node).
 	// Set/cleared via CreateNode / SetNodeOwner; decoupled from the enrollment
 	// credential. Invariant: OwnerTypeGlobal <=> OwnerID == "";
-	// OwnerTypeAccount <=> OwnerID != "".
+	// OwnerTypeAccount / OwnerTypeGroup <=> OwnerID != "".
 	OwnerType commonapi.OwnerType
 	OwnerID   string
 
@@ -61,7 +77,12 @@ type ServiceInfo struct {
 	// when inherited from a node group, retrieve the config via the group.
 	UsedConfig   string
 	ConfigSource commonapi.ServiceSource
-	ConfigOps    []ConfigOp
+	// ConfigSourceID is the node ID or the contributing node group ID, per ConfigSource.
+	// For an inherited config this is the group that actually holds it, which may be
+	// an ancestor of the group the node is a member of.
+	ConfigSourceID string
+	// ConfigOps is the effective, layered list in application order; see NodeGroup.
+	ConfigOps []ConfigOp
 }
 
 // NodeStateChange is delivered on the channel returned by SubscribeNodeStateChanges().
@@ -88,7 +109,8 @@ type NodeStateChange struct {
 	// EnrollmentStateBound rides the connectivity event of its registration;
 	// the intermediate provisioning states (TokenIssued, CertIssued) are not
 	// separately signalled but still appear here in other events' snapshots.
-	// Token/cert expiry-driven transitions are not delivered in real time.
+	// Token/cert expiry-driven transitions are not delivered in real time here;
+	// subscribe via SubscribeEnrollmentEvents for every enrollment transition.
 	EnrollmentState commonapi.EnrollmentState
 }
 
@@ -107,6 +129,43 @@ type OpsResponse struct {
 	ServiceError error
 }
 
+// NodeMetricsPolicy controls node-side metric scraping (snapi.MetricsProvider).
+type NodeMetricsPolicy struct {
+	// ScrapeInterval is the period between scrapes; 0 disables scraping.
+	ScrapeInterval time.Duration
+	// TSDBName, if non-empty, persists every scrape into the framework TSDB of that
+	// name (the same instance InitTSDB(TSDBName) returns), in the measurement
+	// "node_metrics" with tag "node_id" and one field per metric name.
+	TSDBName string
+}
+
+// NodeMetrics is the last scrape of one node.
+type NodeMetrics struct {
+	NodeID    string
+	ScrapedAt time.Time
+	Metrics   map[string]float64
+	// Error is the error of the last scrape, if it failed; Metrics then holds the
+	// last successful scrape.
+	Error error
+}
+
+// AggregatedMetrics aggregates the node metrics of a node group.
+type AggregatedMetrics struct {
+	NodeGroupID string
+	Nodes       int // member nodes whose metrics are included
+	Missing     int // member nodes never scraped or whose last scrape failed; not in Metrics
+	Metrics     map[string]*MetricAggregate
+}
+
+// MetricAggregate summarizes one metric across nodes.
+type MetricAggregate struct {
+	Count int // nodes reporting the metric
+	Sum   float64
+	Min   float64
+	Max   float64
+	Avg   float64
+}
+
 // Link represents a connection between two nodes.
 // Bandwidth is symmetric (upload == download), expressed in bits per second.
 type Link struct {
@@ -124,18 +183,62 @@ type LinkNode struct {
 }
 
 // NodeGroup is a service-scoped collection of nodes within a network.
-// Config and ConfigOps set on the group are inherited by member nodes
-// unless the node has its own direct overrides.
+// Config and ConfigOps set on the group are inherited by member nodes (and by
+// the members of descendant groups) unless the node has its own direct overrides.
+//
+// Groups nest via ParentID, and a node may belong to several groups. Group
+// precedence is by Priority, higher wins; equal priorities are broken by Name,
+// lexically greatest wins, then by the group in the deeper network. Resolution:
+//   - Config: a node-level config wins. Otherwise each member group resolves to
+//     its own Config or, if empty, its nearest ancestor's; the group of highest
+//     precedence among the node's groups wins.
+//   - ConfigOps: the node's groups are visited in ascending precedence and, for
+//     each, its ancestors from the root down and then the group itself. Each
+//     group contributes its ops once, at its first visit, so a shared ancestor
+//     is not applied twice. A node with node-level ops keeps only the ops of
+//     groups with LayerConfigOps set and applies its own ops after them.
 type NodeGroup struct {
 	ID          string
 	NetworkID   string
+	ParentID    string // empty for a top-level group; the parent is in NetworkID or an ancestor network
 	Name        string
 	Description string
+	// Priority orders orthogonal groups a node belongs to; higher wins. Default 0.
+	Priority int
+	// OwnerType / OwnerID record the tenant that created the group through
+	// ASNController.ForOwner; OwnerTypeGlobal (empty OwnerID) otherwise. Only the
+	// owning tenant's view may change the group; the unscoped controller may
+	// change any group.
+	OwnerType commonapi.OwnerType
+	OwnerID   string
+	// LayerConfigOps keeps the group's own ConfigOps on member nodes that have
+	// node-level ops, instead of letting those ops override them. Default false.
+	LayerConfigOps bool
 	// Metadata is an opaque string set by the service via UpdateNodeGroupMetadata().
 	Metadata  string
 	Nodes     []string
 	Config    string
 	ConfigOps []ConfigOp
+	// Rule is the membership rule of a dynamic group; nil for a static group
+	// whose Nodes are maintained via AddNodesToNodeGroup / RemoveNodesFromNodeGroup.
+	Rule *NodeGroupRule
+}
+
+// NodeGroupRule defines the membership of a dynamic node group. The framework
+// re-evaluates it whenever a node in the group's network subtree registers, is created,
+// or changes a matched attribute, and adds or removes members accordingly.
+// Each axis lists alternatives (OR); an empty axis is not filtered; a node must
+// match every non-empty axis (AND). Only nodes in the group's network subtree (the
+// group's network and its subnetworks) are considered; NetworkIDs narrows that
+// to one or more subtrees within it.
+type NodeGroupRule struct {
+	NodeTypes    []commonapi.NodeType
+	Labels       []string // exact match on Node.Label
+	NetworkIDs   []string // subtree match: the network or any of its subnetworks; must lie within the group's subtree
+	DeviceModels []string // exact match on NodeInfo.DeviceInfo.Model
+	Owner        *NodeOwnerFilter
+	// WithService restricts members to nodes that have this service loaded.
+	WithService bool
 }
 
 // ConfigOp is a single persistent configuration directive attached to a node or node group.
@@ -145,6 +248,8 @@ type ConfigOp struct {
 	ID           string
 	ConfigParams string
 	Source       commonapi.ServiceSource
+	// SourceID is the node ID or node group ID the op is directly attached to.
+	SourceID string
 }
 
 // LicenseInfo describes the license currently used by this service.
@@ -156,9 +261,76 @@ type LicenseInfo struct {
 
 	ValidStartTime time.Time
 	ValidEndTime   time.Time
+	// GraceEndTime is the end of the grace period after ValidEndTime, during which
+	// Status is LicenseStatusGrace; equal to ValidEndTime when there is no grace.
+	GraceEndTime time.Time
 
 	// Content contains service-defined license payload fields.
 	Contents map[string]string
+
+	// Entitlements is the typed view of the license; nil for a license that
+	// carries none (everything is then governed by Status alone).
+	Entitlements *LicenseEntitlements
+
+	// ExpiryWarning is set only when OnLicenseChanged is called for a scheduled warning.
+	ExpiryWarning *LicenseExpiryWarning
+}
+
+// LicenseExpiryWarning is one scheduled warning ahead of a license deadline.
+type LicenseExpiryWarning struct {
+	// Deadline is ValidEndTime, or GraceEndTime once the license is in grace.
+	Deadline time.Time
+	// Before is the schedule point that fired, e.g. 7 * 24 * time.Hour.
+	Before time.Duration
+}
+
+// LicenseHistoryEvent is the kind of a LicenseHistoryEntry.
+type LicenseHistoryEvent string
+
+const (
+	LicenseHistoryBound         LicenseHistoryEvent = "bound"          // first UseLicense on this machine
+	LicenseHistoryRenewed       LicenseHistoryEvent = "renewed"        // UseLicense with a new key or extended term
+	LicenseHistoryStatusChanged LicenseHistoryEvent = "status_changed" // Status moved, e.g. active -> grace
+	LicenseHistoryWarning       LicenseHistoryEvent = "warning"        // a scheduled expiry warning was delivered
+)
+
+// LicenseHistoryEntry is one event in GetLicenseHistory.
+type LicenseHistoryEntry struct {
+	Timestamp      time.Time
+	Event          LicenseHistoryEvent
+	LicenseKey     string
+	Status         commonapi.LicenseStatus // status after the event
+	ValidStartTime time.Time
+	ValidEndTime   time.Time
+	GraceEndTime   time.Time
+	// Warning is set for LicenseHistoryWarning.
+	Warning *LicenseExpiryWarning
+}
+
+// LicenseQuotaNodes is the reserved quota name for the number of nodes that
+// have this service, enforced by the framework.
+const LicenseQuotaNodes = "nodes"
+
+// LicenseEntitlements are the typed grants of a license.
+type LicenseEntitlements struct {
+	// Features maps feature name to its grant; a name not present is not entitled.
+	Features map[string]*FeatureEntitlement
+	// Quotas maps quota name to its limit; a name not present is unlimited.
+	Quotas map[string]int64
+}
+
+// FeatureEntitlement is the grant of one licensed feature.
+type FeatureEntitlement struct {
+	Enabled bool
+	// ValidEndTime is the feature's own expiry; zero means it follows the license.
+	ValidEndTime time.Time
+}
+
+// LicenseQuotaUsage is the current usage of one license quota.
+type LicenseQuotaUsage struct {
+	Name  string
+	Limit int64
+	Used  int64
 }
 
 // Node ownership (OwnerType + OwnerID) is a node-level attribute decoupled from
@@ -166,20 +338,78 @@ type LicenseInfo struct {
 // authenticated context and the framework stores it verbatim, enforcing only the
 // OwnerType/OwnerID invariant. The framework does not adjudicate owner identity,
 // entitlement, or tenant isolation — those stay with the service. Ownership is
-// set via CreateNode or ASNController.SetNodeOwner, read on the Node struct, and
-// filtered via ASNController.GetNodesOfNetwork. See workflow/design/PrivateNode.md.
+// set via CreateNode, ASNController.SetNodeOwner, or a two-party OwnerTransfer
+// completed by ASNController.AcceptNodeOwnerTransfer, read on the Node struct,
+// and filtered via ASNController.GetNodesOfNetwork.
+// See workflow/design/PrivateNode.md.
 
 // SetNodeOwnerRequest sets, transfers, or clears a node's ownership.
 type SetNodeOwnerRequest struct {
 	NodeID    string
 	OwnerType commonapi.OwnerType // empty => OwnerTypeGlobal
-	OwnerID   string              // required for OwnerTypeAccount; must be empty otherwise
+	OwnerID   string              // required for OwnerTypeAccount / OwnerTypeGroup; must be empty otherwise
+}
+
+// OwnerTransferState is the state of a two-party ownership transfer.
+type OwnerTransferState string
+
+const (
+	OwnerTransferPending   OwnerTransferState = "pending"   // awaiting the recipient
+	OwnerTransferAccepted  OwnerTransferState = "accepted"  // ownership moved to the recipient
+	OwnerTransferRejected  OwnerTransferState = "rejected"  // declined by the recipient
+	OwnerTransferCancelled OwnerTransferState = "cancelled" // withdrawn by the initiator
+	OwnerTransferExpired   OwnerTransferState = "expired"   // TTL elapsed while pending
+)
+
+// InitiateOwnerTransferRequest opens a pending transfer of a node to another owner.
+type InitiateOwnerTransferRequest struct {
+	NodeID string
+	// FromOwnerType/FromOwnerID assert the current owner; both must match the
+	// node. Empty FromOwnerType => OwnerTypeGlobal, as everywhere else.
+	FromOwnerType commonapi.OwnerType
+	FromOwnerID   string
+	// ToOwnerType is required and must be OwnerTypeAccount or OwnerTypeGroup;
+	// it is not defaulted. To release a node to OwnerTypeGlobal, use SetNodeOwner.
+	ToOwnerType commonapi.OwnerType
+	ToOwnerID   string
+	TTLSeconds  int64 // 0 => framework default
+	Reason      string
+}
+
+// OwnerTransfer is a two-party node ownership transfer.
+type OwnerTransfer struct {
+	ID            string
+	NodeID        string
+	FromOwnerType commonapi.OwnerType
+	FromOwnerID   string
+	ToOwnerType   commonapi.OwnerType
+	ToOwnerID     string
+	State         OwnerTransferState
+	Reason        string // initiator's reason, or the rejecter's/canceller's once closed
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+	ClosedAt      time.Time // zero while pending
+}
+
+// OwnerTransferFilter selects transfers for ListNodeOwnerTransfers. Each slice
+// lists alternatives (OR); an empty slice is not filtered; set fields combine with AND.
+type OwnerTransferFilter struct {
+	NodeIDs      []string
+	FromOwnerIDs []string
+	ToOwnerIDs   []string
+	States       []OwnerTransferState
 }
 
-// NodeOwnerFilter optionally restricts GetNodesOfNetwork by ownership. The two
-// axes are independent; an empty slice means that axis is not filtered, and when
-// both are set a node must match both (AND).
+// NodeOwnerFilter optionally restricts GetNodesOfNetwork by ownership. The
+// axes are independent; an empty value means that axis is not filtered, and when
+// several are set a node must match all of them (AND).
 type NodeOwnerFilter struct {
 	OwnerTypes []commonapi.OwnerType // empty => no owner-type filter
 	OwnerIDs   []string              // empty => no owner-id filter
+	// VisibleToAccountID matches nodes the account sees directly or through its
+	// groups: OwnerTypeAccount with this account's ID, or OwnerTypeGroup with
+	// "<service>/<group>" for any group the account belongs to in the calling
+	// service's IAM (resolved at call time via iam.Instance.AccountGroupList).
+	// Groups of other services are not considered. Empty => no visibility filter.
+	VisibleToAccountID string
 }
@@ -83,6 +83,11 @@ type ASNServiceNode interface {
 	// asynchronously via ClusterMasterService.OnSlaveServiceState.
 	SendStopToSlave(slaveName string) error
 
+	// GetSlaveServiceState queries the named slave for the current state of this
+	// service and blocks until it replies or the call times out. The reply also
+	// refreshes SlaveNodeInfo.ServiceState; it does not trigger OnSlaveServiceState.
+	GetSlaveServiceState(slaveName string) (commonapi.ServiceState, error)
+
 	// SendApplyOpsToSlave sends an ops command to the named slave and blocks
 	// until the slave replies or the call times out.
 	SendApplyOpsToSlave(slaveName, opCmd, opParams string) (resp string, err error)
@@ -99,6 +104,28 @@ type ASNServiceNode interface {
 	// blocks until the slave acknowledges or the call times out.
 	SendDeleteConfigOpsToSlave(slaveName string, params []string) (resp string, err error)
 
+	// BroadcastApplyOpsToSlaves sends an ops command to every slave matched by
+	// filter and returns immediately; one SlaveOpsResponse per targeted slave
+	// streams into resChan as slaves reply or time out, and resChan is closed
+	// after the last one. Slaves are addressed concurrently, each under the same
+	// per-call timeout as SendApplyOpsToSlave. A matched slave that is not
+	// connected or has not loaded this service gets a response with
+	// TransportError set instead of being skipped.
+	// If paramErr != nil (e.g. ErrNotMasterNode), resChan is nil.
+	BroadcastApplyOpsToSlaves(opCmd, opParams string, filter SlaveFilter) (resChan <-chan *SlaveOpsResponse, paramErr error)
+
+	// BroadcastAddConfigOpsToSlaves is the fan-out form of SendAddConfigOpsToSlave,
+	// with the same result semantics as BroadcastApplyOpsToSlaves.
+	BroadcastAddConfigOpsToSlaves(params []string, filter SlaveFilter) (resChan <-chan *SlaveOpsResponse, paramErr error)
+
+	// BroadcastUpdateConfigOpToSlaves is the fan-out form of SendUpdateConfigOpToSlave,
+	// with the same result semantics as BroadcastApplyOpsToSlaves.
+	BroadcastUpdateConfigOpToSlaves(oldParam, newParam string, filter SlaveFilter) (resChan <-chan *SlaveOpsResponse, paramErr error)
+
+	// BroadcastDeleteConfigOpsToSlaves is the fan-out form of SendDeleteConfigOpsToSlave,
+	// with the same result semantics as BroadcastApplyOpsToSlaves.
+	BroadcastDeleteConfigOpsToSlaves(params []string, filter SlaveFilter) (resChan <-chan *SlaveOpsResponse, paramErr error)
+
 	// -------------------------------------------------------------------------
 	// Cross-Service Data Access
 	// Enables data exchange between services co-located on the same node.
@@ -20,6 +20,19 @@ type StaticResource interface {
 	SharedData() (aggregated, subscribable []string)
 }
 
+// MetricsProvider is optionally implemented by an ASNService to publish node-side
+// metrics. The framework detects it by type assertion after Init() and, while the
+// service is ServiceStateRunning, calls GetMetrics at the interval configured on
+// the controller (ASNController.SetNodeMetricsPolicy). The controller reads the
+// results per node and aggregated per node group.
+type MetricsProvider interface {
+	// GetMetrics returns the current value of each metric, keyed by metric name.
+	// Concurrent; must return promptly. Maintain snapshots in background
+	// goroutines; do not compute on the call path. Names should be stable across
+	// calls and versions so aggregates and persisted series stay meaningful.
+	GetMetrics() (map[string]float64, error)
+}
+
 // ASNService is the interface implemented by a service running on a Service Node.
 // The framework calls these methods to manage the service's lifetime and behavior.
 //
@@ -4,6 +4,7 @@ package snapi
 
 import (
 	"errors"
+	"time"
 
 	commonapi "asn.amiasys.com/asn-service-api/v26/common"
 )
@@ -46,4 +47,46 @@ type SlaveNodeInfo struct {
 	Management *commonapi.Management
 	DeviceInfo *commonapi.DeviceInfo
 	GrpcUrl    string
+
+	// Telemetry below is the master's last known view; nil pointers and zero
+	// values mean never reported.
+
+	// ServiceState is the last service state reported by the slave (as delivered
+	// to ASNService.OnSlaveServiceState); nil until the slave has reported one.
+	ServiceState *commonapi.ServiceState
+	// Version is the service version the slave reported at registration; nil if unknown.
+	Version *commonapi.Version
+	// LastHeartbeat is when the master last heard from the slave's stream.
+	LastHeartbeat time.Time
+	// RTT is the most recently measured round-trip latency of the stream.
+	RTT time.Duration
+	// ReconnectCount is how many times the stream has re-established since the master started.
+	ReconnectCount int
+}
+
+// SlaveFilter selects the slaves targeted by the Broadcast*ToSlaves methods.
+// The zero value targets every slave returned by GetSlaveNodes.
+type SlaveFilter struct {
+	// Names restricts the target to these slaves; empty => all.
+	Names []string
+	// ConnectedOnly skips slaves whose stream is not established instead of
+	// reporting ErrSlaveNotConnected for them.
+	ConnectedOnly bool
+}
+
+// SlaveOpsResponse is one slave's result of a Broadcast*ToSlaves call.
+// When TransportError != nil, Response and ServiceError are undefined.
+type SlaveOpsResponse struct {
+	Timestamp time.Time
+	SlaveName string
+
+	// TransportError is set when the slave could not be reached, had not loaded
+	// the service, or did not reply in time (ErrSlaveNotConnected,
+	// ErrSlaveServiceNotFound, or a timeout error).
+	TransportError error
+
+	// Response is the resp string returned by the slave's service method.
+	Response string
+	// ServiceError is the error returned by the same method.
+	ServiceError error
 }